@@ -0,0 +1,128 @@
+package xerr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StepError records one failed step captured by a batch runner, including
+// its zero-based position among all steps passed to that runner.
+type StepError struct {
+	Tip   string
+	Err   error
+	Index int
+}
+
+// MultiError aggregates every StepError observed by a batch runner or a
+// collect-all parallel group. It implements the Go 1.20 multi-unwrap shape
+// (Unwrap() []error) so errors.Is/errors.As traverse each cause.
+type MultiError struct {
+	errs []StepError
+}
+
+// Error joins every step's tip and error into a single summary line.
+func (m *MultiError) Error() string {
+	parts := make([]string, len(m.errs))
+	for i, se := range m.errs {
+		parts[i] = fmt.Sprintf("%s: %v", se.Tip, se.Err)
+	}
+	return fmt.Sprintf("%d step(s) failed: %s", len(m.errs), strings.Join(parts, "; "))
+}
+
+// Unwrap returns every step's underlying error, enabling errors.Is/errors.As
+// to traverse each cause (Go 1.20+ multi-unwrap).
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.errs))
+	for i, se := range m.errs {
+		errs[i] = se.Err
+	}
+	return errs
+}
+
+// Errors returns every failed step, in the order they were recorded.
+func (m *MultiError) Errors() []StepError {
+	return append([]StepError(nil), m.errs...)
+}
+
+// SummaryMiddleware observes the final {success, failed} tally of a batch
+// once its done function is called, as opposed to per-step middleware
+// which observes one step at a time.
+type SummaryMiddleware func(ctx context.Context, success, failed int)
+
+// BatchSummaryMiddleware logs the final {success, failed} tally of a batch
+// to stdout. Pass it to a batch runner's done function.
+//
+// Example usage:
+//
+//	run, done := xerr.NewBatchError(ctx, xerr.LoggerMiddleware)
+//	...
+//	merr := done(xerr.BatchSummaryMiddleware)
+func BatchSummaryMiddleware(ctx context.Context, success, failed int) {
+	fmt.Printf("batch closed: %d succeeded, %d failed\n", success, failed)
+}
+
+// NewBatchError creates a reusable runner for independent steps that should
+// NOT short-circuit: unlike NewGlobalError, every registered step runs
+// regardless of earlier failures, and failures accumulate into a
+// *MultiError instead of stopping the flow.
+//
+// The returned run function executes and observes one step, exactly like
+// NewGlobalError's runner (mids are invoked exactly once per step, tip is
+// never used for wrapping). Call the returned done function once every
+// step has run to retrieve the aggregated result (nil if every step
+// succeeded) and to fire any summary middleware with the final tally.
+//
+// Use case: independent operations where partial success must be
+// reported (e.g. validating a form, running independent cleanup steps,
+// fan-out jobs).
+//
+// Example:
+//
+//	run, done := xerr.NewBatchError(ctx, xerr.LoggerMiddleware)
+//
+//	run("validate name", validateNameFn)
+//	run("validate email", validateEmailFn)
+//	run("validate address", validateAddressFn)
+//
+//	if merr := done(xerr.BatchSummaryMiddleware); merr != nil {
+//	    for _, se := range merr.Errors() {
+//	        log.Printf("%s failed: %v", se.Tip, se.Err)
+//	    }
+//	}
+func NewBatchError(ctx context.Context, mids ...middleware) (run func(tip string, fn func(context.Context) error), done func(summaryMids ...SummaryMiddleware) *MultiError) {
+	var (
+		errs    []StepError
+		success int
+		idx     int
+	)
+
+	run = func(tip string, fn func(context.Context) error) {
+		stepCtx := context.WithValue(ctx, execInfoKey{}, &ExecInfo{Attempts: 1, StartedAt: time.Now()})
+		e := fn(stepCtx)
+
+		for _, mid := range mids {
+			mid(stepCtx, e, tip)
+		}
+
+		if e != nil {
+			errs = append(errs, StepError{Tip: tip, Err: e, Index: idx})
+		} else {
+			success++
+		}
+		idx++
+	}
+
+	done = func(summaryMids ...SummaryMiddleware) *MultiError {
+		for _, sm := range summaryMids {
+			sm(ctx, success, len(errs))
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		return &MultiError{errs: append([]StepError(nil), errs...)}
+	}
+
+	return run, done
+}
@@ -0,0 +1,130 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestNewBatchError_AllStepsRun ensures every step runs even after a failure
+func TestNewBatchError_AllStepsRun(t *testing.T) {
+	ctx := context.Background()
+	run, done := NewBatchError(ctx)
+
+	var executed []string
+	run("step 1", func(ctx context.Context) error {
+		executed = append(executed, "step 1")
+		return errors.New("boom")
+	})
+	run("step 2", func(ctx context.Context) error {
+		executed = append(executed, "step 2")
+		return nil
+	})
+	run("step 3", func(ctx context.Context) error {
+		executed = append(executed, "step 3")
+		return errors.New("kaboom")
+	})
+
+	if len(executed) != 3 {
+		t.Fatalf("expected all 3 steps to execute, got: %v", executed)
+	}
+
+	merr := done()
+	if merr == nil {
+		t.Fatal("expected a non-nil MultiError")
+	}
+	if len(merr.Errors()) != 2 {
+		t.Fatalf("expected 2 failed steps, got %d", len(merr.Errors()))
+	}
+}
+
+// TestNewBatchError_NoFailures tests that done returns nil when everything succeeds
+func TestNewBatchError_NoFailures(t *testing.T) {
+	ctx := context.Background()
+	run, done := NewBatchError(ctx)
+
+	run("step 1", func(ctx context.Context) error { return nil })
+	run("step 2", func(ctx context.Context) error { return nil })
+
+	if merr := done(); merr != nil {
+		t.Errorf("expected nil MultiError, got: %v", merr)
+	}
+}
+
+// TestNewBatchError_StepErrorIndexAndTip verifies StepError metadata
+func TestNewBatchError_StepErrorIndexAndTip(t *testing.T) {
+	ctx := context.Background()
+	run, done := NewBatchError(ctx)
+	err1 := errors.New("err1")
+	err2 := errors.New("err2")
+
+	run("ok step", func(ctx context.Context) error { return nil })
+	run("fail 1", func(ctx context.Context) error { return err1 })
+	run("fail 2", func(ctx context.Context) error { return err2 })
+
+	merr := done()
+	steps := merr.Errors()
+	if steps[0].Tip != "fail 1" || steps[0].Index != 1 || steps[0].Err != err1 {
+		t.Errorf("unexpected first StepError: %+v", steps[0])
+	}
+	if steps[1].Tip != "fail 2" || steps[1].Index != 2 || steps[1].Err != err2 {
+		t.Errorf("unexpected second StepError: %+v", steps[1])
+	}
+}
+
+// TestMultiError_Unwrap tests Go 1.20 multi-unwrap support
+func TestMultiError_Unwrap(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	ctx := context.Background()
+	run, done := NewBatchError(ctx)
+
+	run("step", func(ctx context.Context) error { return sentinel })
+	merr := done()
+
+	if !errors.Is(merr, sentinel) {
+		t.Error("expected errors.Is to find the sentinel error via multi-unwrap")
+	}
+}
+
+// TestMultiError_Error tests the aggregated error message
+func TestMultiError_Error(t *testing.T) {
+	ctx := context.Background()
+	run, done := NewBatchError(ctx)
+
+	run("step a", func(ctx context.Context) error { return errors.New("bad a") })
+	merr := done()
+
+	msg := merr.Error()
+	if msg == "" {
+		t.Error("expected non-empty error message")
+	}
+}
+
+// TestNewBatchError_MiddlewareCalledPerStep tests middleware is invoked for every step
+func TestNewBatchError_MiddlewareCalledPerStep(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	mid := func(ctx context.Context, err error, tip string) {
+		calls = append(calls, tip)
+	}
+
+	run, done := NewBatchError(ctx, mid)
+	run("a", func(ctx context.Context) error { return nil })
+	run("b", func(ctx context.Context) error { return errors.New("x") })
+	done()
+
+	if len(calls) != 2 || calls[0] != "a" || calls[1] != "b" {
+		t.Errorf("expected middleware called for a and b in order, got: %v", calls)
+	}
+}
+
+// TestBatchSummaryMiddleware_NoPanic ensures it runs without panicking
+func TestBatchSummaryMiddleware_NoPanic(t *testing.T) {
+	ctx := context.Background()
+	run, done := NewBatchError(ctx)
+
+	run("ok", func(ctx context.Context) error { return nil })
+	run("bad", func(ctx context.Context) error { return errors.New("bad") })
+
+	done(BatchSummaryMiddleware)
+}
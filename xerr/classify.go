@@ -0,0 +1,123 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Class categorizes the outcome of a step for metrics and alerting, e.g.
+// so a dashboard can distinguish errors worth paging on from ones that
+// aren't.
+type Class int
+
+const (
+	ClassUnknown Class = iota
+	ClassTransient
+	ClassPermanent
+	ClassCanceled
+	ClassTimeout
+	// ClassOK marks a step that succeeded. Classify never returns it (it
+	// only classifies errors); NewMetricsMiddleware uses it directly for
+	// successful steps so "things are fine" has its own metric bucket,
+	// distinct from ClassUnknown.
+	ClassOK
+)
+
+// String renders c for use as a metric label or log field.
+func (c Class) String() string {
+	switch c {
+	case ClassTransient:
+		return "transient"
+	case ClassPermanent:
+		return "permanent"
+	case ClassCanceled:
+		return "canceled"
+	case ClassTimeout:
+		return "timeout"
+	case ClassOK:
+		return "ok"
+	default:
+		return "unknown"
+	}
+}
+
+// Sentinel errors for step outcomes. Classify maps ErrCanceled to
+// ClassCanceled and ErrTimeout to ClassTimeout; ErrShortCircuited has no
+// built-in rule and classifies as ClassUnknown.
+var (
+	ErrCanceled       = errors.New("xerr: step canceled")
+	ErrTimeout        = errors.New("xerr: step timed out")
+	ErrShortCircuited = errors.New("xerr: step short-circuited by a prior failure")
+)
+
+type classMatcher struct {
+	matcher func(error) bool
+	class   Class
+}
+
+var (
+	classMu       sync.Mutex
+	customClasses []classMatcher
+)
+
+// RegisterClass adds a matcher consulted by Classify before its built-in
+// rules, so downstream systems can bucket domain-specific errors (e.g. a
+// "not found" error that should be ClassPermanent but never alert).
+// Matchers are consulted in registration order; the first match wins.
+func RegisterClass(matcher func(error) bool, class Class) {
+	classMu.Lock()
+	defer classMu.Unlock()
+	customClasses = append(customClasses, classMatcher{matcher, class})
+}
+
+// Classify buckets err for metrics/alerting. Callers should filter out nil
+// errors before classifying; Classify(nil) returns ClassUnknown.
+//
+// An error that matches none of the built-in rules or a RegisterClass
+// matcher also returns ClassUnknown, not ClassPermanent: ClassPermanent is
+// reserved for errors actually known to be terminal. This keeps Classify's
+// default agreeing with IsRetriable's default (any unmatched error is
+// retriable), so metrics and retry behavior don't disagree about whether
+// an unclassified error is worth retrying.
+func Classify(err error) Class {
+	if err == nil {
+		return ClassUnknown
+	}
+
+	classMu.Lock()
+	matchers := append([]classMatcher(nil), customClasses...)
+	classMu.Unlock()
+
+	for _, m := range matchers {
+		if m.matcher(err) {
+			return m.class
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, ErrCanceled):
+		return ClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrTimeout):
+		return ClassTimeout
+	case errors.Is(err, ErrCircuitOpen):
+		return ClassTransient
+	default:
+		return ClassUnknown
+	}
+}
+
+// MiddlewareV2 is a richer observer than middleware: alongside the raw
+// error and tip, it receives the error's Class and the step's ExecInfo
+// (e.g. attempt count). Use AsMiddleware to adapt it for
+// NewGlobalError/NewBatchError/Group, which only accept the plain
+// middleware shape.
+type MiddlewareV2 func(ctx context.Context, err error, tip string, class Class, info ExecInfo)
+
+// AsMiddleware adapts m into a plain middleware, classifying err and
+// reading ExecInfo from ctx on every call.
+func (m MiddlewareV2) AsMiddleware() middleware {
+	return func(ctx context.Context, err error, tip string) {
+		m(ctx, err, tip, Classify(err), ExecInfoFromContext(ctx))
+	}
+}
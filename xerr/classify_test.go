@@ -0,0 +1,164 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestClassify_BuiltinRules tests the built-in classification rules
+func TestClassify_BuiltinRules(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, ClassUnknown},
+		{"context canceled", context.Canceled, ClassCanceled},
+		{"xerr canceled sentinel", ErrCanceled, ClassCanceled},
+		{"context deadline exceeded", context.DeadlineExceeded, ClassTimeout},
+		{"xerr timeout sentinel", ErrTimeout, ClassTimeout},
+		{"circuit open", ErrCircuitOpen, ClassTransient},
+		{"plain unmatched error", errors.New("boom"), ClassUnknown},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(tc.err); got != tc.want {
+				t.Errorf("Classify(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestRegisterClass_CustomMatcher tests a user-registered class wins over built-ins
+func TestRegisterClass_CustomMatcher(t *testing.T) {
+	sentinel := errors.New("rate limited")
+	RegisterClass(func(err error) bool {
+		return errors.Is(err, sentinel)
+	}, ClassTransient)
+
+	if got := Classify(sentinel); got != ClassTransient {
+		t.Errorf("expected ClassTransient for a registered matcher, got %v", got)
+	}
+}
+
+// TestClass_String tests the label rendering used by metrics
+func TestClass_String(t *testing.T) {
+	cases := map[Class]string{
+		ClassUnknown:   "unknown",
+		ClassTransient: "transient",
+		ClassPermanent: "permanent",
+		ClassCanceled:  "canceled",
+		ClassTimeout:   "timeout",
+	}
+	for class, want := range cases {
+		if got := class.String(); got != want {
+			t.Errorf("Class(%d).String() = %q, want %q", class, got, want)
+		}
+	}
+}
+
+// TestMiddlewareV2_AsMiddleware tests the V2 adapter receives class and ExecInfo
+func TestMiddlewareV2_AsMiddleware(t *testing.T) {
+	ctx := context.Background()
+	var gotClass Class
+	var gotErr error
+	var gotTip string
+
+	v2 := MiddlewareV2(func(ctx context.Context, err error, tip string, class Class, info ExecInfo) {
+		gotErr = err
+		gotTip = tip
+		gotClass = class
+	})
+
+	runner := NewGlobalError(ctx, v2.AsMiddleware())
+
+	var err error
+	sentinel := errors.New("boom")
+	runner(&err, "step", func(ctx context.Context) error {
+		return sentinel
+	})
+
+	if gotErr != sentinel {
+		t.Errorf("expected MiddlewareV2 to observe the sentinel error, got: %v", gotErr)
+	}
+	if gotTip != "step" {
+		t.Errorf("expected tip 'step', got: %s", gotTip)
+	}
+	if gotClass != ClassUnknown {
+		t.Errorf("expected ClassUnknown for an unmatched error, got: %v", gotClass)
+	}
+}
+
+// stubRecorder captures ObserveStep calls for assertions
+type stubRecorder struct {
+	tip      string
+	class    Class
+	duration time.Duration
+	called   bool
+}
+
+func (s *stubRecorder) ObserveStep(tip string, class Class, duration time.Duration) {
+	s.tip = tip
+	s.class = class
+	s.duration = duration
+	s.called = true
+}
+
+// TestNewMetricsMiddleware_RecordsOutcome tests the metrics middleware reports tip/class/duration
+func TestNewMetricsMiddleware_RecordsOutcome(t *testing.T) {
+	ctx := context.Background()
+	rec := &stubRecorder{}
+	runner := NewGlobalError(ctx, NewMetricsMiddleware(rec))
+
+	var err error
+	runner(&err, "fetch data", func(ctx context.Context) error {
+		time.Sleep(time.Millisecond)
+		return errors.New("boom")
+	})
+
+	if !rec.called {
+		t.Fatal("expected the recorder to be called")
+	}
+	if rec.tip != "fetch data" {
+		t.Errorf("expected tip 'fetch data', got: %s", rec.tip)
+	}
+	if rec.class != ClassUnknown {
+		t.Errorf("expected ClassUnknown for an unmatched error, got: %v", rec.class)
+	}
+	if rec.duration <= 0 {
+		t.Errorf("expected a positive duration, got: %v", rec.duration)
+	}
+}
+
+// TestNewMetricsMiddleware_SuccessIsClassOK tests a successful step is
+// recorded as ClassOK, not Classify(nil)'s ClassUnknown, so dashboards can
+// tell "fine" apart from "failed and unclassifiable".
+func TestNewMetricsMiddleware_SuccessIsClassOK(t *testing.T) {
+	ctx := context.Background()
+	rec := &stubRecorder{}
+	runner := NewGlobalError(ctx, NewMetricsMiddleware(rec))
+
+	var err error
+	runner(&err, "fetch data", func(ctx context.Context) error {
+		return nil
+	})
+
+	if !rec.called {
+		t.Fatal("expected the recorder to be called")
+	}
+	if rec.class != ClassOK {
+		t.Errorf("expected ClassOK for a successful step, got: %v", rec.class)
+	}
+}
+
+// TestNewMetricsMiddleware_NilRecorderUsesNoop tests nil falls back to NoopRecorder without panicking
+func TestNewMetricsMiddleware_NilRecorderUsesNoop(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx, NewMetricsMiddleware(nil))
+
+	var err error
+	runner(&err, "step", func(ctx context.Context) error { return nil })
+}
@@ -0,0 +1,167 @@
+package xerr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Step is a single unit of work for Group/ParallelAll, identified by Tip
+// for middleware observation.
+type Step struct {
+	Tip string
+	Fn  func(context.Context) error
+}
+
+// groupOptions holds settings configured via a GroupOption.
+type groupOptions struct {
+	concurrency int
+}
+
+// GroupOption configures Group/ParallelAll.
+type GroupOption func(*groupOptions)
+
+// Parallel sets the worker-pool size for a Group/ParallelAll call. n <= 0
+// means unbounded (one goroutine per step, the default).
+func Parallel(n int) GroupOption {
+	return func(o *groupOptions) { o.concurrency = n }
+}
+
+// SyncMiddleware wraps mid with a mutex so it's safe to call concurrently,
+// for middleware that isn't already safe for concurrent invocation.
+// Group and ParallelAll invoke mids from multiple goroutines, so any
+// middleware that isn't inherently concurrency-safe must be wrapped with
+// SyncMiddleware before being passed to them.
+func SyncMiddleware(mid middleware) middleware {
+	var mu sync.Mutex
+	return func(ctx context.Context, err error, tip string) {
+		mu.Lock()
+		defer mu.Unlock()
+		mid(ctx, err, tip)
+	}
+}
+
+// Group runs steps concurrently with a bounded worker pool (see Parallel),
+// cancelling the derived context on the first failure (fail-fast, like
+// errgroup.Group), and waits for every in-flight step to drain before
+// returning. The first observed error is assigned to *err without
+// wrapping, preserving this package's no-wrapping invariant. mids are
+// invoked exactly once per step, from whichever goroutine ran that step;
+// wrap an unsafe middleware with SyncMiddleware first.
+//
+// Use case: independent operations that should run in parallel but still
+// stop the whole batch as soon as one fails (e.g. fan-out reads that feed
+// a single downstream step).
+//
+// Example:
+//
+//	var err error
+//	xerr.Group(ctx, &err, "fanout", steps, nil, xerr.Parallel(4))
+func Group(ctx context.Context, err *error, tip string, steps []Step, mids []middleware, opts ...GroupOption) {
+	if *err != nil || len(steps) == 0 {
+		return
+	}
+
+	o := resolveGroupOptions(len(steps), opts)
+
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, o.concurrency)
+
+	for _, step := range steps {
+		step := step
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			stepCtx := context.WithValue(groupCtx, execInfoKey{}, &ExecInfo{Attempts: 1, StartedAt: time.Now()})
+			e := step.Fn(stepCtx)
+			for _, mid := range mids {
+				mid(stepCtx, e, step.Tip)
+			}
+
+			if e != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = e
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		*err = firstErr
+	}
+}
+
+// ParallelAll runs every step concurrently regardless of individual
+// failures, aggregating every failure into a *MultiError (nil if every
+// step succeeded) instead of cancelling on the first one. Use this over
+// Group when callers need collect-all rather than fail-fast semantics.
+func ParallelAll(ctx context.Context, steps []Step, mids []middleware, opts ...GroupOption) *MultiError {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	o := resolveGroupOptions(len(steps), opts)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []StepError
+	)
+	sem := make(chan struct{}, o.concurrency)
+
+	for i, step := range steps {
+		i, step := i, step
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			stepCtx := context.WithValue(ctx, execInfoKey{}, &ExecInfo{Attempts: 1, StartedAt: time.Now()})
+			e := step.Fn(stepCtx)
+			for _, mid := range mids {
+				mid(stepCtx, e, step.Tip)
+			}
+
+			if e != nil {
+				mu.Lock()
+				errs = append(errs, StepError{Tip: step.Tip, Err: e, Index: i})
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &MultiError{errs: errs}
+}
+
+// resolveGroupOptions applies opts over a default concurrency of
+// stepCount (i.e. unbounded), clamping any non-positive override back to
+// stepCount.
+func resolveGroupOptions(stepCount int, opts []GroupOption) groupOptions {
+	o := groupOptions{concurrency: stepCount}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.concurrency <= 0 {
+		o.concurrency = stepCount
+	}
+	return o
+}
@@ -0,0 +1,199 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGroup_AllSucceed tests that *err stays nil when every step succeeds
+func TestGroup_AllSucceed(t *testing.T) {
+	ctx := context.Background()
+	var ran int32
+	steps := []Step{
+		{Tip: "a", Fn: func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return nil }},
+		{Tip: "b", Fn: func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return nil }},
+		{Tip: "c", Fn: func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return nil }},
+	}
+
+	var err error
+	Group(ctx, &err, "fanout", steps, nil)
+
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if ran != 3 {
+		t.Errorf("expected all 3 steps to run, got %d", ran)
+	}
+}
+
+// TestGroup_FirstErrorAssignedWithoutWrapping tests the no-wrapping invariant
+func TestGroup_FirstErrorAssignedWithoutWrapping(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("boom")
+	steps := []Step{
+		{Tip: "a", Fn: func(ctx context.Context) error { return nil }},
+		{Tip: "b", Fn: func(ctx context.Context) error { return sentinel }},
+	}
+
+	var err error
+	Group(ctx, &err, "fanout", steps, nil)
+
+	if err != sentinel {
+		t.Errorf("expected the exact sentinel error, got: %v", err)
+	}
+}
+
+// TestGroup_CancelsRemainingStepsOnFailure tests fail-fast cancellation propagates
+func TestGroup_CancelsRemainingStepsOnFailure(t *testing.T) {
+	ctx := context.Background()
+	var cancelled int32
+	steps := []Step{
+		{Tip: "fails fast", Fn: func(ctx context.Context) error {
+			return errors.New("immediate failure")
+		}},
+		{Tip: "long running", Fn: func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				atomic.AddInt32(&cancelled, 1)
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		}},
+	}
+
+	var err error
+	Group(ctx, &err, "fanout", steps, nil)
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if cancelled != 1 {
+		t.Errorf("expected the long-running step to observe cancellation, got cancelled=%d", cancelled)
+	}
+}
+
+// TestGroup_MiddlewareCalledOncePerStep tests mids fire exactly once per step
+func TestGroup_MiddlewareCalledOncePerStep(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var seen []string
+	mid := SyncMiddleware(func(ctx context.Context, err error, tip string) {
+		mu.Lock()
+		seen = append(seen, tip)
+		mu.Unlock()
+	})
+
+	steps := []Step{
+		{Tip: "a", Fn: func(ctx context.Context) error { return nil }},
+		{Tip: "b", Fn: func(ctx context.Context) error { return nil }},
+		{Tip: "c", Fn: func(ctx context.Context) error { return nil }},
+	}
+
+	var err error
+	Group(ctx, &err, "fanout", steps, []middleware{mid})
+
+	if len(seen) != 3 {
+		t.Fatalf("expected 3 middleware calls, got %d: %v", len(seen), seen)
+	}
+}
+
+// TestGroup_BoundedConcurrency tests Parallel(n) limits concurrent steps
+func TestGroup_BoundedConcurrency(t *testing.T) {
+	ctx := context.Background()
+	var current, maxConcurrent int32
+
+	steps := make([]Step, 10)
+	for i := range steps {
+		steps[i] = Step{Tip: "step", Fn: func(ctx context.Context) error {
+			n := atomic.AddInt32(&current, 1)
+			for {
+				m := atomic.LoadInt32(&maxConcurrent)
+				if n <= m || atomic.CompareAndSwapInt32(&maxConcurrent, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+			return nil
+		}}
+	}
+
+	var err error
+	Group(ctx, &err, "fanout", steps, nil, Parallel(2))
+
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if maxConcurrent > 2 {
+		t.Errorf("expected at most 2 concurrent steps, observed %d", maxConcurrent)
+	}
+}
+
+// TestParallelAll_RunsEveryStepAndAggregates tests collect-all semantics
+func TestParallelAll_RunsEveryStepAndAggregates(t *testing.T) {
+	ctx := context.Background()
+	var ran int32
+	steps := []Step{
+		{Tip: "a", Fn: func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return errors.New("fail a") }},
+		{Tip: "b", Fn: func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return nil }},
+		{Tip: "c", Fn: func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return errors.New("fail c") }},
+	}
+
+	merr := ParallelAll(ctx, steps, nil)
+
+	if ran != 3 {
+		t.Fatalf("expected all 3 steps to run, got %d", ran)
+	}
+	if merr == nil {
+		t.Fatal("expected a non-nil MultiError")
+	}
+	if len(merr.Errors()) != 2 {
+		t.Errorf("expected 2 failures, got %d", len(merr.Errors()))
+	}
+}
+
+// TestParallelAll_NilWhenAllSucceed tests the nil fast-path
+func TestParallelAll_NilWhenAllSucceed(t *testing.T) {
+	ctx := context.Background()
+	steps := []Step{
+		{Tip: "a", Fn: func(ctx context.Context) error { return nil }},
+	}
+
+	if merr := ParallelAll(ctx, steps, nil); merr != nil {
+		t.Errorf("expected nil MultiError, got: %v", merr)
+	}
+}
+
+// TestGroup_NoSteps tests a no-op call with an empty step slice
+func TestGroup_NoSteps(t *testing.T) {
+	ctx := context.Background()
+	var err error
+	Group(ctx, &err, "fanout", nil, nil)
+
+	if err != nil {
+		t.Errorf("expected no error for an empty group, got: %v", err)
+	}
+}
+
+// TestGroup_ShortCircuitsOnPriorError tests Group skips entirely if *err is already set
+func TestGroup_ShortCircuitsOnPriorError(t *testing.T) {
+	ctx := context.Background()
+	firstErr := errors.New("already failed")
+	err := firstErr
+
+	Group(ctx, &err, "fanout", []Step{
+		{Tip: "should not run", Fn: func(ctx context.Context) error {
+			t.Fatal("step should not execute")
+			return nil
+		}},
+	}, nil)
+
+	if err != firstErr {
+		t.Errorf("expected original error to be preserved, got: %v", err)
+	}
+}
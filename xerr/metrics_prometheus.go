@@ -0,0 +1,43 @@
+//go:build xerr_prometheus
+
+package xerr
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusRecorder is a Recorder backed by Prometheus client_golang
+// metrics: xerr_step_total{tip,class} and xerr_step_duration_seconds{tip}.
+// Construct one with NewPrometheusRecorder and pass it to
+// NewMetricsMiddleware.
+//
+// Build with -tags xerr_prometheus to link client_golang.
+type PrometheusRecorder struct {
+	total    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewPrometheusRecorder registers xerr's metrics on reg and returns a
+// PrometheusRecorder that reports to them.
+func NewPrometheusRecorder(reg prometheus.Registerer) *PrometheusRecorder {
+	r := &PrometheusRecorder{
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xerr_step_total",
+			Help: "Count of xerr steps by tip and outcome class.",
+		}, []string{"tip", "class"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "xerr_step_duration_seconds",
+			Help: "Duration of xerr steps by tip.",
+		}, []string{"tip"}),
+	}
+	reg.MustRegister(r.total, r.duration)
+	return r
+}
+
+// ObserveStep implements Recorder.
+func (r *PrometheusRecorder) ObserveStep(tip string, class Class, duration time.Duration) {
+	r.total.WithLabelValues(tip, class.String()).Inc()
+	r.duration.WithLabelValues(tip).Observe(duration.Seconds())
+}
@@ -0,0 +1,245 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Runner is the callable returned by NewGlobalError (and
+// NewGlobalErrorWithSpans). It's a named function type, rather than a bare
+// func, so decorators like WithPolicy can hang additional methods off the
+// same value callers already have; calling it directly still works exactly
+// as before since Runner's underlying type is a plain function.
+type Runner func(err *error, tip string, fn func(context.Context) error)
+
+// ExecInfo carries metadata about how a step executed. run attaches one
+// per step to the context passed to fn and to mids, so policies such as
+// Retry can record attempt counts and mids can read them back via
+// ExecInfoFromContext.
+type ExecInfo struct {
+	Attempts  int
+	StartedAt time.Time
+}
+
+type execInfoKey struct{}
+
+// ExecInfoFromContext returns the ExecInfo for the current step, or a
+// default ExecInfo (Attempts: 1, zero StartedAt) if none is attached, e.g.
+// ctx did not originate from run/NewGlobalError.
+func ExecInfoFromContext(ctx context.Context) ExecInfo {
+	if info, ok := ctx.Value(execInfoKey{}).(*ExecInfo); ok {
+		return *info
+	}
+	return ExecInfo{Attempts: 1}
+}
+
+// Policy decorates a step function with cross-cutting execution behavior
+// such as retries, timeouts, or circuit breaking. Policies compose like
+// decorators: WithPolicy applies the first policy outermost.
+type Policy func(fn func(context.Context) error) func(context.Context) error
+
+// WithPolicy runs fn through r the same way r normally would, except fn is
+// first wrapped by the given policies, composed so the first policy listed
+// is outermost (it sees the call before and after every inner policy).
+//
+// Example:
+//
+//	run := xerr.NewGlobalError(ctx, xerr.LoggerMiddleware)
+//
+//	var err error
+//	run.WithPolicy(&err, "fetch data", fetchFn,
+//	    xerr.Retry(3, xerr.ExpBackoff(50*time.Millisecond, 2*time.Second)),
+//	    xerr.Timeout(5*time.Second),
+//	    xerr.Breaker("upstream-api"),
+//	)
+func (r Runner) WithPolicy(err *error, tip string, fn func(context.Context) error, policies ...Policy) {
+	for i := len(policies) - 1; i >= 0; i-- {
+		fn = policies[i](fn)
+	}
+	r(err, tip, fn)
+}
+
+// RetryClassifier lets an error type mark itself as terminal (or
+// retriable) regardless of IsRetriable's default heuristic, e.g. a
+// validation error that should never be retried.
+type RetryClassifier interface {
+	Retriable() bool
+}
+
+// IsRetriable reports whether err should be retried by Retry. By default
+// any non-nil error is retriable except context.Canceled and
+// context.DeadlineExceeded; implement RetryClassifier on an error type to
+// override this per error.
+func IsRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rc RetryClassifier
+	if errors.As(err, &rc) {
+		return rc.Retriable()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return true
+}
+
+// BackoffFunc returns the delay to wait before retry attempt n (0-indexed:
+// attempt 0 is the delay before the second try).
+type BackoffFunc func(attempt int) time.Duration
+
+// ExpBackoff returns a BackoffFunc that doubles base on each attempt,
+// capped at max, with up to 50% jitter to avoid synchronized retries
+// across callers (a thundering herd against the same downstream).
+func ExpBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := base * time.Duration(1<<uint(attempt))
+		if d <= 0 || d > max {
+			d = max
+		}
+		jitter := time.Duration(rand.Int63n(int64(d) + 1))
+		return d/2 + jitter/2
+	}
+}
+
+// Retry re-invokes fn on retriable errors (see IsRetriable) up to attempts
+// total tries, waiting according to backoff between attempts and honoring
+// ctx.Done(). The ExecInfo attached to ctx by run has its Attempts field
+// updated before each try, so middleware can distinguish first-try
+// successes from eventual successes.
+//
+// attempts <= 0 is treated as 1 (fn still runs exactly once, with no
+// retries) rather than silently skipping fn and reporting success: a
+// computed or configured attempts value that ends up 0 should not make a
+// step look like it succeeded without ever running.
+func Retry(attempts int, backoff BackoffFunc) Policy {
+	if attempts <= 0 {
+		attempts = 1
+	}
+	return func(fn func(context.Context) error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			info, _ := ctx.Value(execInfoKey{}).(*ExecInfo)
+
+			var err error
+			for attempt := 0; attempt < attempts; attempt++ {
+				if info != nil {
+					info.Attempts = attempt + 1
+				}
+
+				err = fn(ctx)
+				if err == nil || !IsRetriable(err) || attempt == attempts-1 {
+					return err
+				}
+
+				select {
+				case <-ctx.Done():
+					return err
+				case <-time.After(backoff(attempt)):
+				}
+			}
+			return err
+		}
+	}
+}
+
+// Timeout wraps fn's context in context.WithTimeout(ctx, d) for the
+// duration of the call.
+func Timeout(d time.Duration) Policy {
+	return func(fn func(context.Context) error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return fn(ctx)
+		}
+	}
+}
+
+// ErrCircuitOpen is returned by a step wrapped with Breaker when that
+// breaker's circuit is open: the call is rejected without invoking fn.
+var ErrCircuitOpen = errors.New("xerr: circuit breaker open")
+
+const (
+	breakerFailureThreshold = 5
+	breakerCooldown         = 30 * time.Second
+)
+
+type breakerStateKind int
+
+const (
+	breakerClosed breakerStateKind = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breakerState struct {
+	mu               sync.Mutex
+	state            breakerStateKind
+	consecutiveFails int
+	openedAt         time.Time
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*breakerState{}
+)
+
+// Breaker maintains per-name circuit state in a package-level registry,
+// shared across every step that uses the same name. After
+// breakerFailureThreshold consecutive failures the circuit opens and
+// rejects calls with ErrCircuitOpen until breakerCooldown elapses, at
+// which point a single call is let through half-open to probe recovery.
+func Breaker(name string) Policy {
+	breakersMu.Lock()
+	b, ok := breakers[name]
+	if !ok {
+		b = &breakerState{}
+		breakers[name] = b
+	}
+	breakersMu.Unlock()
+
+	return func(fn func(context.Context) error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			b.mu.Lock()
+			probing := false
+			switch b.state {
+			case breakerOpen:
+				if time.Since(b.openedAt) < breakerCooldown {
+					b.mu.Unlock()
+					return ErrCircuitOpen
+				}
+				// Cooldown elapsed: only the caller that wins this
+				// check-and-set (the mutex serializes concurrent callers
+				// here) transitions to half-open and probes fn. Every
+				// other concurrent caller now sees breakerHalfOpen below
+				// and is rejected until the probe resolves, instead of
+				// all of them piling onto a service that just started
+				// recovering.
+				b.state = breakerHalfOpen
+				probing = true
+			case breakerHalfOpen:
+				b.mu.Unlock()
+				return ErrCircuitOpen
+			}
+			b.mu.Unlock()
+
+			err := fn(ctx)
+
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if err != nil {
+				b.consecutiveFails++
+				if probing || b.consecutiveFails >= breakerFailureThreshold {
+					b.state = breakerOpen
+					b.openedAt = time.Now()
+				}
+				return err
+			}
+			b.consecutiveFails = 0
+			b.state = breakerClosed
+			return nil
+		}
+	}
+}
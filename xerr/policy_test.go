@@ -0,0 +1,307 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRunner_WithPolicy_NoPolicies tests WithPolicy with zero policies behaves like a plain call
+func TestRunner_WithPolicy_NoPolicies(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+
+	var err error
+	runner.WithPolicy(&err, "step", func(ctx context.Context) error {
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+// TestRetry_SucceedsAfterFailures tests retry re-invokes fn until success
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+
+	var attempts int
+	var err error
+	runner.WithPolicy(&err, "flaky", func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	}, Retry(5, func(attempt int) time.Duration { return time.Millisecond }))
+
+	if err != nil {
+		t.Errorf("expected eventual success, got: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+// TestRetry_NonPositiveAttemptsStillRunsOnce tests that a non-positive
+// attempts value doesn't silently skip fn and report success.
+func TestRetry_NonPositiveAttemptsStillRunsOnce(t *testing.T) {
+	for _, attempts := range []int{0, -1, -5} {
+		ctx := context.Background()
+		runner := NewGlobalError(ctx)
+
+		var ran int
+		var err error
+		runner.WithPolicy(&err, "step", func(ctx context.Context) error {
+			ran++
+			return errors.New("boom")
+		}, Retry(attempts, func(attempt int) time.Duration { return time.Millisecond }))
+
+		if ran != 1 {
+			t.Errorf("Retry(%d, ...): expected fn to run exactly once, ran %d times", attempts, ran)
+		}
+		if err == nil {
+			t.Errorf("Retry(%d, ...): expected the real error to surface, got nil", attempts)
+		}
+	}
+}
+
+// TestRetry_GivesUpAfterMaxAttempts tests retry stops after exhausting attempts
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+
+	var attempts int
+	var err error
+	runner.WithPolicy(&err, "always fails", func(ctx context.Context) error {
+		attempts++
+		return errors.New("permanent")
+	}, Retry(3, func(attempt int) time.Duration { return time.Millisecond }))
+
+	if err == nil {
+		t.Error("expected an error after exhausting retries")
+	}
+	if attempts != 3 {
+		t.Errorf("expected exactly 3 attempts, got %d", attempts)
+	}
+}
+
+// terminalError implements RetryClassifier to force non-retriable behavior
+type terminalError struct{ msg string }
+
+func (e *terminalError) Error() string   { return e.msg }
+func (e *terminalError) Retriable() bool { return false }
+
+// TestRetry_RespectsRetryClassifier tests a RetryClassifier error stops retry early
+func TestRetry_RespectsRetryClassifier(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+
+	var attempts int
+	var err error
+	runner.WithPolicy(&err, "validation", func(ctx context.Context) error {
+		attempts++
+		return &terminalError{msg: "invalid input"}
+	}, Retry(5, func(attempt int) time.Duration { return time.Millisecond }))
+
+	if attempts != 1 {
+		t.Errorf("expected exactly 1 attempt for a terminal error, got %d", attempts)
+	}
+	if err == nil {
+		t.Error("expected the terminal error to be set")
+	}
+}
+
+// TestIsRetriable_ContextErrors tests context errors are not retriable by default
+func TestIsRetriable_ContextErrors(t *testing.T) {
+	if IsRetriable(context.Canceled) {
+		t.Error("context.Canceled should not be retriable")
+	}
+	if IsRetriable(context.DeadlineExceeded) {
+		t.Error("context.DeadlineExceeded should not be retriable")
+	}
+	if !IsRetriable(errors.New("boom")) {
+		t.Error("a plain error should be retriable by default")
+	}
+	if IsRetriable(nil) {
+		t.Error("nil should not be retriable")
+	}
+}
+
+// TestTimeout_CancelsLongRunningStep tests Timeout cancels fn's context
+func TestTimeout_CancelsLongRunningStep(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+
+	var err error
+	runner.WithPolicy(&err, "slow", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}, Timeout(10*time.Millisecond))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+// TestBreaker_OpensAfterConsecutiveFailures tests the breaker opens and rejects fast
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+	name := "test-breaker-opens"
+
+	for i := 0; i < breakerFailureThreshold; i++ {
+		var err error
+		runner.WithPolicy(&err, "call", func(ctx context.Context) error {
+			return errors.New("downstream down")
+		}, Breaker(name))
+	}
+
+	var err error
+	var called bool
+	runner.WithPolicy(&err, "call", func(ctx context.Context) error {
+		called = true
+		return nil
+	}, Breaker(name))
+
+	if called {
+		t.Error("fn should not be invoked while the circuit is open")
+	}
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got: %v", err)
+	}
+}
+
+// TestBreaker_ResetsOnSuccess tests the breaker closes again after a success
+func TestBreaker_ResetsOnSuccess(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+	name := "test-breaker-resets"
+
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		var failErr error
+		runner.WithPolicy(&failErr, "call", func(ctx context.Context) error {
+			return errors.New("downstream down")
+		}, Breaker(name))
+	}
+
+	var err error
+	runner.WithPolicy(&err, "call", func(ctx context.Context) error {
+		return nil
+	}, Breaker(name))
+
+	if err != nil {
+		t.Errorf("expected success, got: %v", err)
+	}
+
+	breakersMu.Lock()
+	state := breakers[name].consecutiveFails
+	breakersMu.Unlock()
+	if state != 0 {
+		t.Errorf("expected consecutive failures reset to 0, got %d", state)
+	}
+}
+
+// TestBreaker_HalfOpenAllowsOnlyOneProbe tests that once the cooldown
+// elapses, concurrent callers don't all pile onto the recovering service:
+// only one proceeds to call fn, the rest are rejected with ErrCircuitOpen.
+func TestBreaker_HalfOpenAllowsOnlyOneProbe(t *testing.T) {
+	ctx := context.Background()
+	runner := NewGlobalError(ctx)
+	name := "test-breaker-half-open-single-probe"
+
+	for i := 0; i < breakerFailureThreshold; i++ {
+		var err error
+		runner.WithPolicy(&err, "call", func(ctx context.Context) error {
+			return errors.New("downstream down")
+		}, Breaker(name))
+	}
+
+	// Simulate the cooldown having elapsed without a real sleep.
+	breakersMu.Lock()
+	breakers[name].openedAt = time.Now().Add(-breakerCooldown - time.Second)
+	breakersMu.Unlock()
+
+	const callers = 5
+	var probeCount int32
+	var wg sync.WaitGroup
+	var rejected int32
+
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			runner.WithPolicy(&err, "call", func(ctx context.Context) error {
+				atomic.AddInt32(&probeCount, 1)
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			}, Breaker(name))
+			if errors.Is(err, ErrCircuitOpen) {
+				atomic.AddInt32(&rejected, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if probeCount != 1 {
+		t.Errorf("expected exactly 1 caller to probe fn, got %d", probeCount)
+	}
+	if rejected != callers-1 {
+		t.Errorf("expected %d callers rejected with ErrCircuitOpen, got %d", callers-1, rejected)
+	}
+}
+
+// TestExecInfoFromContext_Default tests the default fallback (Attempts: 1)
+func TestExecInfoFromContext_Default(t *testing.T) {
+	info := ExecInfoFromContext(context.Background())
+	if info.Attempts != 1 {
+		t.Errorf("expected default Attempts to be 1, got %d", info.Attempts)
+	}
+}
+
+// TestExecInfoFromContext_ReflectsRetryAttempts tests middleware sees the final attempt count
+func TestExecInfoFromContext_ReflectsRetryAttempts(t *testing.T) {
+	ctx := context.Background()
+	var observed ExecInfo
+	mid := func(ctx context.Context, err error, tip string) {
+		observed = ExecInfoFromContext(ctx)
+	}
+	runner := NewGlobalError(ctx, mid)
+
+	var attempts int
+	var err error
+	runner.WithPolicy(&err, "flaky", func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	}, Retry(5, func(attempt int) time.Duration { return time.Millisecond }))
+
+	if observed.Attempts != 3 {
+		t.Errorf("expected middleware to observe 3 attempts, got %d", observed.Attempts)
+	}
+}
+
+// TestExpBackoff_WithinBounds tests the returned delay never exceeds max
+func TestExpBackoff_WithinBounds(t *testing.T) {
+	backoff := ExpBackoff(10*time.Millisecond, 100*time.Millisecond)
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoff(attempt)
+		if d > 100*time.Millisecond {
+			t.Errorf("attempt %d: delay %v exceeds max", attempt, d)
+		}
+		if d < 0 {
+			t.Errorf("attempt %d: delay %v is negative", attempt, d)
+		}
+	}
+}
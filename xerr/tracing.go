@@ -0,0 +1,86 @@
+package xerr
+
+import "context"
+
+// contextKey is an unexported type for xerr's own context keys, avoiding
+// the bare-string-key collisions that context.Value is prone to.
+type contextKey string
+
+const (
+	traceIDContextKey contextKey = "xerr_trace_id"
+	spanIDContextKey  contextKey = "xerr_span_id"
+)
+
+// WithTraceID returns a context carrying id, readable back via
+// LoggerMiddleware and any tracing integration. Prefer this over setting a
+// bare string key directly with context.WithValue.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey, id)
+}
+
+// WithSpanID returns a context carrying id, mirroring WithTraceID.
+func WithSpanID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, spanIDContextKey, id)
+}
+
+// getSpanID extracts span_id from context, if any was attached.
+func getSpanID(ctx context.Context) string {
+	if id, ok := ctx.Value(spanIDContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// SpanMiddleware wraps a step's entire execution, before and after,
+// unlike middleware which only observes the outcome once fn has already
+// returned. It receives the step's ctx and tip, and returns a (possibly
+// derived) context to thread into fn plus a closer invoked with the
+// step's final error once fn returns.
+type SpanMiddleware func(ctx context.Context, tip string) (context.Context, func(err error))
+
+// NewGlobalErrorWithSpans is NewGlobalError's counterpart for steps that
+// need before/after wrapping around fn (e.g. tracing spans) rather than
+// pure after-the-fact observation. spanMids wrap outermost-first: the
+// first entry starts before and closes after all the others.
+//
+// mids continue to work exactly as with NewGlobalError, observing the
+// final outcome after fn returns; they receive the context produced by
+// spanMids, so e.g. LoggerMiddleware picks up a trace_id/span_id attached
+// by a tracing SpanMiddleware.
+//
+// Example:
+//
+//	run := xerr.NewGlobalErrorWithSpans(ctx,
+//	    []xerr.SpanMiddleware{xerr.NewTracingMiddleware(tracer)},
+//	    xerr.LoggerMiddleware,
+//	)
+//
+//	var err error
+//	run(&err, "fetch data", fetchFn)
+func NewGlobalErrorWithSpans(ctx context.Context, spanMids []SpanMiddleware, mids ...middleware) Runner {
+	return func(err *error, tip string, fn func(context.Context) error) {
+		runWithSpans(ctx, err, tip, fn, spanMids, mids...)
+	}
+}
+
+// runWithSpans is NewGlobalErrorWithSpans' internal execution, composing
+// spanMids around fn before delegating to run for the usual short-circuit
+// and after-the-fact middleware handling.
+func runWithSpans(ctx context.Context, err *error, tip string, fn func(context.Context) error, spanMids []SpanMiddleware, mids ...middleware) {
+	if *err != nil {
+		return // short-circuit
+	}
+
+	closers := make([]func(error), 0, len(spanMids))
+	for _, sm := range spanMids {
+		var closer func(error)
+		ctx, closer = sm(ctx, tip)
+		closers = append(closers, closer)
+	}
+
+	run(ctx, err, tip, fn, mids...)
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i](*err)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build xerr_otel
+
+package xerr
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracingMiddleware returns a SpanMiddleware that starts a child span
+// per step named after its tip, records the error via span.RecordError
+// and sets the span's status on failure, and ends the span once the step
+// completes. Register it with NewGlobalErrorWithSpans.
+//
+// Build with -tags xerr_otel to link go.opentelemetry.io/otel.
+func NewTracingMiddleware(tracer trace.Tracer) SpanMiddleware {
+	return func(ctx context.Context, tip string) (context.Context, func(err error)) {
+		spanCtx, span := tracer.Start(ctx, tip)
+
+		sc := span.SpanContext()
+		spanCtx = WithTraceID(spanCtx, sc.TraceID().String())
+		spanCtx = WithSpanID(spanCtx, sc.SpanID().String())
+
+		return spanCtx, func(err error) {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	}
+}
+
+// ParentSpanContext extracts the span context of ctx's active span, if
+// any, so a runner bound to ctx participates in an existing trace (e.g.
+// one started by an inbound HTTP request) instead of starting a new root
+// span.
+func ParentSpanContext(ctx context.Context) (trace.SpanContext, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	return sc, sc.IsValid()
+}
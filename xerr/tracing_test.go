@@ -0,0 +1,104 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestWithTraceID_GetTraceID tests the safe typed-key trace_id path
+func TestWithTraceID_GetTraceID(t *testing.T) {
+	ctx := WithTraceID(context.Background(), "trace-abc")
+
+	if id := getTraceID(ctx); id != "trace-abc" {
+		t.Errorf("expected 'trace-abc', got: %s", id)
+	}
+}
+
+// TestWithSpanID tests span_id extraction
+func TestWithSpanID(t *testing.T) {
+	ctx := WithSpanID(context.Background(), "span-xyz")
+
+	if id := getSpanID(ctx); id != "span-xyz" {
+		t.Errorf("expected 'span-xyz', got: %s", id)
+	}
+}
+
+// TestGetSpanID_Absent tests that getSpanID returns empty string when unset
+func TestGetSpanID_Absent(t *testing.T) {
+	if id := getSpanID(context.Background()); id != "" {
+		t.Errorf("expected empty string, got: %s", id)
+	}
+}
+
+// TestNewGlobalErrorWithSpans_ThreadsContext tests the before/after context threading
+func TestNewGlobalErrorWithSpans_ThreadsContext(t *testing.T) {
+	ctx := context.Background()
+
+	var spanStarted, spanClosed bool
+	var closedWithErr error
+
+	spanMid := func(ctx context.Context, tip string) (context.Context, func(err error)) {
+		spanStarted = true
+		ctx = WithTraceID(ctx, "span-trace")
+		return ctx, func(err error) {
+			spanClosed = true
+			closedWithErr = err
+		}
+	}
+
+	var observedTraceID string
+	mid := func(ctx context.Context, err error, tip string) {
+		observedTraceID = getTraceID(ctx)
+	}
+
+	run := NewGlobalErrorWithSpans(ctx, []SpanMiddleware{spanMid}, mid)
+
+	var err error
+	sentinel := errors.New("boom")
+	run(&err, "step", func(ctx context.Context) error {
+		return sentinel
+	})
+
+	if !spanStarted {
+		t.Error("expected span to have started")
+	}
+	if !spanClosed {
+		t.Error("expected span to have closed")
+	}
+	if closedWithErr != sentinel {
+		t.Errorf("expected span closer to receive the step error, got: %v", closedWithErr)
+	}
+	if observedTraceID != "span-trace" {
+		t.Errorf("expected mids to see the span-derived context, got trace_id: %s", observedTraceID)
+	}
+	if err != sentinel {
+		t.Errorf("expected *err to be set to the step error, got: %v", err)
+	}
+}
+
+// TestNewGlobalErrorWithSpans_ShortCircuit tests that a prior error skips the step and spans
+func TestNewGlobalErrorWithSpans_ShortCircuit(t *testing.T) {
+	ctx := context.Background()
+	var spanStarted bool
+	spanMid := func(ctx context.Context, tip string) (context.Context, func(err error)) {
+		spanStarted = true
+		return ctx, func(err error) {}
+	}
+
+	run := NewGlobalErrorWithSpans(ctx, []SpanMiddleware{spanMid})
+
+	firstErr := errors.New("already failed")
+	err := firstErr
+	run(&err, "step", func(ctx context.Context) error {
+		t.Fatal("fn should not execute")
+		return nil
+	})
+
+	if spanStarted {
+		t.Error("span should not start when short-circuiting")
+	}
+	if err != firstErr {
+		t.Errorf("expected original error to be preserved, got: %v", err)
+	}
+}
@@ -4,11 +4,13 @@
 //   - Global error handling with automatic short-circuit (fail-fast semantics)
 //   - No error wrapping - preserves original errors
 //   - Middleware support for logging, metrics, and tracing
+//   - SpanMiddleware support (NewGlobalErrorWithSpans) for before/after
+//     wrapping such as tracing spans
 //   - Context-aware execution
 //
 // Quick Start:
 //
-//	ctx := context.WithValue(context.Background(), "trace_id", "req-123")
+//	ctx := xerr.WithTraceID(context.Background(), "req-123")
 //	run := xerr.NewGlobalError(ctx, xerr.LoggerMiddleware)
 //
 //	var err error
@@ -32,6 +34,7 @@ package xerr
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // middleware is a function that observes operation execution.
@@ -45,12 +48,17 @@ import (
 type middleware func(ctx context.Context, err error, tip string)
 
 // run executes a single operation with middleware support.
-// This is an internal function. External callers should use WithGlobalError.
+// This is an internal function. External callers should use NewGlobalError.
 func run(ctx context.Context, err *error, tip string, fn func(context.Context) error, mids ...middleware) {
 	if *err != nil {
 		return // short-circuit
 	}
 
+	// Attach a per-step ExecInfo so policies (see policy.go) can record
+	// attempt counts, and mids can read them back via ExecInfoFromContext.
+	info := &ExecInfo{Attempts: 1, StartedAt: time.Now()}
+	ctx = context.WithValue(ctx, execInfoKey{}, info)
+
 	e := fn(ctx)
 
 	// Middleware uses tip for tracing, cannot modify e
@@ -79,7 +87,7 @@ func run(ctx context.Context, err *error, tip string, fn func(context.Context) e
 //
 // Example:
 //
-//	ctx := context.WithValue(context.Background(), "trace_id", "req-123")
+//	ctx := xerr.WithTraceID(context.Background(), "req-123")
 //	run := xerr.NewGlobalError(ctx, xerr.LoggerMiddleware)
 //
 //	var err error
@@ -92,49 +100,101 @@ func run(ctx context.Context, err *error, tip string, fn func(context.Context) e
 //	    // Handle the first error that occurred
 //	}
 //
-// Note: For future extension, consider WithBatchError for collecting all errors without short-circuit.
-func NewGlobalError(ctx context.Context, mids ...middleware) func(*error, string, func(context.Context) error) {
+// Note: For independent steps that must all run regardless of earlier
+// failures, use NewBatchError instead, which collects every failure into
+// a MultiError rather than short-circuiting.
+func NewGlobalError(ctx context.Context, mids ...middleware) Runner {
 	return func(err *error, tip string, fn func(context.Context) error) {
 		run(ctx, err, tip, fn, mids...)
 	}
 }
 
 // LoggerMiddleware logs operation execution status to stdout.
-// It extracts trace_id from context for request tracing.
+// It extracts trace_id (and span_id, if present) from context for request
+// tracing.
 // Format:
 //   - Success: ✅[trace_id] tip
 //   - Failure: ❌[trace_id] tip: error
+//   - With an active span: trace_id is rendered as "trace_id/span_id"
 //
 // Example usage:
 //
 //	run := xerr.NewGlobalError(ctx, xerr.LoggerMiddleware)
 func LoggerMiddleware(ctx context.Context, err error, tip string) {
+	id := getTraceID(ctx)
+	if span := getSpanID(ctx); span != "" {
+		id = id + "/" + span
+	}
 	if err == nil {
-		fmt.Printf("✅[%s] %s\n", getTraceID(ctx), tip)
+		fmt.Printf("✅[%s] %s\n", id, tip)
 	} else {
-		fmt.Printf("❌[%s] %s: %v\n", getTraceID(ctx), tip, err)
+		fmt.Printf("❌[%s] %s: %v\n", id, tip, err)
 	}
 }
 
-// MetricsMiddleware is a placeholder for custom metrics collection.
-// Uncomment and implement to integrate with Prometheus, StatsD, etc.
+// Recorder receives step outcome metrics from NewMetricsMiddleware. The
+// default NoopRecorder does nothing; see PrometheusRecorder (built with
+// the xerr_prometheus build tag) for a real adapter.
+type Recorder interface {
+	// ObserveStep records one completed step, shaped for metrics like
+	// xerr_step_total{tip,class} and xerr_step_duration_seconds{tip}.
+	ObserveStep(tip string, class Class, duration time.Duration)
+}
+
+type noopRecorder struct{}
+
+func (noopRecorder) ObserveStep(string, Class, time.Duration) {}
+
+// NoopRecorder is the Recorder used when NewMetricsMiddleware is called
+// with a nil Recorder.
+var NoopRecorder Recorder = noopRecorder{}
+
+// NewMetricsMiddleware builds a middleware that records every step's
+// outcome class and duration via rec. This finally implements the metrics
+// integration point this package previously only sketched in comments.
 //
-// Example implementation:
+// A successful step (err == nil) is recorded as ClassOK, not
+// Classify(nil): Classify's ClassUnknown means "an error we couldn't
+// classify", and conflating that with "no error at all" would make
+// xerr_step_total{tip,class="unknown"} mix genuine failures with every
+// passing step, defeating the point of the metric.
 //
-//	func MetricsMiddleware(ctx context.Context, ok bool, tip string, err error) {
-//	    if !ok {
-//	        prometheus.CounterInc("operation_failure", "operation", tip)
-//	    }
-//	}
+// Example usage:
 //
-// func MetricsMiddleware(ctx context.Context, ok bool, tip string, err error) {
-// 	// Placeholder: integrate with your metrics system
-// 	// e.g., prometheus.CounterInc("step_failure", "operation", tip)
-// }
+//	run := xerr.NewGlobalError(ctx, xerr.NewMetricsMiddleware(promRecorder))
+func NewMetricsMiddleware(rec Recorder) middleware {
+	if rec == nil {
+		rec = NoopRecorder
+	}
+	return func(ctx context.Context, err error, tip string) {
+		info := ExecInfoFromContext(ctx)
+		var duration time.Duration
+		if !info.StartedAt.IsZero() {
+			duration = time.Since(info.StartedAt)
+		}
+		class := ClassOK
+		if err != nil {
+			class = Classify(err)
+		}
+		rec.ObserveStep(tip, class, duration)
+	}
+}
 
 // getTraceID extracts trace_id from context for request tracing.
 // Returns "unknown" if trace_id is not found or has wrong type.
+//
+// It prefers the typed traceIDContextKey set by WithTraceID; callers
+// should always use WithTraceID going forward.
+//
+// Deprecated fallback: for backwards compatibility only, getTraceID still
+// recognizes a bare string "trace_id" key set directly via
+// context.WithValue. That pattern is unsafe per Go context conventions
+// (it can collide with keys set by unrelated packages); nothing in this
+// package's non-test code sets it, and new code must use WithTraceID.
 func getTraceID(ctx context.Context) string {
+	if id, ok := ctx.Value(traceIDContextKey).(string); ok {
+		return id
+	}
 	if id, ok := ctx.Value("trace_id").(string); ok {
 		return id
 	}